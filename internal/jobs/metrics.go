@@ -0,0 +1,24 @@
+package jobs
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	jobRunsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "background_job_runs_total",
+		Help: "Total number of job runs, by job name.",
+	}, []string{"job"})
+
+	jobFailuresTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "background_job_failures_total",
+		Help: "Total number of failed job runs, by job name.",
+	}, []string{"job"})
+
+	jobDurationSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "background_job_duration_seconds",
+		Help:    "Job run duration in seconds, by job name.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"job"})
+)
@@ -0,0 +1,216 @@
+// Package jobs provides a small ticker-driven scheduler for named
+// background jobs, with bounded concurrency, retry-with-backoff, and
+// per-job metrics.
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Func is the work a scheduled job performs on each tick. It should honor
+// ctx cancellation so the scheduler can stop it promptly during shutdown.
+type Func func(ctx context.Context) error
+
+// Stats is a point-in-time snapshot of a job's run history.
+type Stats struct {
+	Runs         uint64
+	Failures     uint64
+	LastDuration time.Duration
+	LastError    string
+}
+
+// Scheduler runs named jobs on their own tickers, subject to a shared
+// concurrency limit. A job whose previous invocation is still running
+// when its ticker fires is skipped for that tick rather than piling up
+// goroutines, and a failing job is retried with exponential backoff and
+// jitter before the scheduler waits for the next tick.
+type Scheduler struct {
+	logger *slog.Logger
+	sem    chan struct{}
+
+	mu   sync.Mutex
+	jobs map[string]*job
+}
+
+type job struct {
+	name     string
+	interval time.Duration
+	fn       Func
+	running  atomic.Bool
+
+	mu    sync.Mutex
+	stats Stats
+}
+
+// New creates a Scheduler whose jobs never run more than maxConcurrent at
+// a time.
+func New(logger *slog.Logger, maxConcurrent int) *Scheduler {
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	return &Scheduler{
+		logger: logger,
+		sem:    make(chan struct{}, maxConcurrent),
+		jobs:   make(map[string]*job),
+	}
+}
+
+// Register adds a job that runs fn every interval once the scheduler is
+// started. Register must be called before Run.
+func (s *Scheduler) Register(name string, interval time.Duration, fn Func) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[name] = &job{name: name, interval: interval, fn: fn}
+}
+
+// Run starts a ticker per registered job and blocks until ctx is
+// cancelled, at which point all jobs stop together.
+func (s *Scheduler) Run(ctx context.Context) {
+	s.mu.Lock()
+	jobs := make([]*job, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		jobs = append(jobs, j)
+	}
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, j := range jobs {
+		wg.Add(1)
+		go func(j *job) {
+			defer wg.Done()
+			s.runTicker(ctx, j)
+		}(j)
+	}
+	wg.Wait()
+}
+
+func (s *Scheduler) runTicker(ctx context.Context, j *job) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.tick(ctx, j)
+		}
+	}
+}
+
+func (s *Scheduler) tick(ctx context.Context, j *job) {
+	if !j.running.CompareAndSwap(false, true) {
+		s.logger.Warn("job still running, skipping tick", "job", j.name)
+		return
+	}
+	defer j.running.Store(false)
+
+	select {
+	case s.sem <- struct{}{}:
+	case <-ctx.Done():
+		return
+	}
+	defer func() { <-s.sem }()
+
+	s.runWithRetry(ctx, j)
+}
+
+func (s *Scheduler) runWithRetry(ctx context.Context, j *job) {
+	const maxAttempts = 3
+	backoff := 250 * time.Millisecond
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		start := time.Now()
+		err := j.fn(ctx)
+		duration := time.Since(start)
+
+		// A job that returns an error because its ctx was cancelled just
+		// stopped for shutdown, not a real failure; don't count it against
+		// the job's failure metrics or log it as an error.
+		cancelled := err != nil && ctx.Err() != nil
+
+		s.record(j, duration, err, cancelled)
+		jobRunsTotal.WithLabelValues(j.name).Inc()
+		jobDurationSeconds.WithLabelValues(j.name).Observe(duration.Seconds())
+
+		if err == nil {
+			return
+		}
+
+		if cancelled {
+			s.logger.Info("job stopped by shutdown", "job", j.name, "attempt", attempt)
+			return
+		}
+
+		jobFailuresTotal.WithLabelValues(j.name).Inc()
+		s.logger.Error("job failed", "job", j.name, "attempt", attempt, "error", err)
+
+		if attempt == maxAttempts {
+			return
+		}
+
+		wait := backoff + time.Duration(rand.Int63n(int64(backoff)))
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return
+		}
+		backoff *= 2
+	}
+}
+
+func (s *Scheduler) record(j *job, duration time.Duration, err error, cancelled bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.stats.Runs++
+	j.stats.LastDuration = duration
+	switch {
+	case err == nil:
+		j.stats.LastError = ""
+	case cancelled:
+		// Leave LastError/Failures as they were; shutdown isn't a failure.
+	default:
+		j.stats.Failures++
+		j.stats.LastError = err.Error()
+	}
+}
+
+// Stats returns a snapshot of the named job's run history. The second
+// return value is false if no job with that name was registered.
+func (s *Scheduler) Stats(name string) (Stats, bool) {
+	s.mu.Lock()
+	j, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return Stats{}, false
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.stats, true
+}
+
+// AllStats returns a snapshot of every registered job's run history,
+// keyed by job name.
+func (s *Scheduler) AllStats() map[string]Stats {
+	s.mu.Lock()
+	jobs := make([]*job, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		jobs = append(jobs, j)
+	}
+	s.mu.Unlock()
+
+	report := make(map[string]Stats, len(jobs))
+	for _, j := range jobs {
+		j.mu.Lock()
+		report[j.name] = j.stats
+		j.mu.Unlock()
+	}
+	return report
+}
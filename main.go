@@ -2,102 +2,315 @@ package main
 
 import (
 	"context"
-	"log"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"sync/atomic"
 	"syscall"
 	"time"
-)
 
-var (
-	requestCount uint64
+	"github.com/thenewkenya/init/internal/jobs"
 )
 
+var ready atomic.Bool
+
+type requestIDKey struct{}
+
 func main() {
-	logger := log.New(os.Stdout, "", log.LstdFlags|log.LUTC)
+	logger := newLogger()
+
+	logger.Info("service starting")
 
-	logger.Println("service starting")
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
 	// Periodic background logs
-	go heartbeat(logger, 5*time.Second)
-	go backgroundWorker(logger, 7*time.Second)
+	go heartbeat(ctx, 5*time.Second)
+
+	scheduler := jobs.New(logger, schedulerConcurrency)
+	scheduler.Register("background", 7*time.Second, func(ctx context.Context) error {
+		return runBackgroundJob(ctx, logger)
+	})
+	go scheduler.Run(ctx)
 
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		atomic.AddUint64(&requestCount, 1)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("hello\n"))
+	})
 
-		logger.Printf(
-			"request received method=%s path=%s remote=%s",
-			r.Method,
-			r.URL.Path,
-			r.RemoteAddr,
-		)
+	mux.HandleFunc("/livez", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	})
 
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !ready.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("shutting down\n"))
+			return
+		}
 		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("hello\n"))
+		w.Write([]byte("ok"))
 	})
 
+	// Kept for compatibility with existing health checks that haven't
+	// migrated to /livez and /readyz yet.
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		if !ready.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("shutting down\n"))
+			return
+		}
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("ok"))
 	})
 
+	ready.Store(true)
+	serviceUp.Set(1)
+
+	go serveMetrics(ctx, logger, scheduler)
+
+	handler := http.TimeoutHandler(requestID(accessLog(logger, mux)), requestTimeout, "request timed out\n")
+
 	server := &http.Server{
-		Addr:    ":8090",
-		Handler: mux,
+		Addr:              ":8090",
+		Handler:           handler,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+		ReadTimeout:       readTimeout,
+		ReadHeaderTimeout: readHeaderTimeout,
+		WriteTimeout:      writeTimeout,
+		IdleTimeout:       idleTimeout,
 	}
 
 	go func() {
-		logger.Println("http server listening on :8090")
+		logger.Info("http server listening", "addr", ":8090")
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Printf("server error: %v", err)
+			logger.Error("server error", "error", err)
 			os.Exit(1)
 		}
 	}()
 
 	// Wait for shutdown signal
-	sig := make(chan os.Signal, 1)
-	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	<-ctx.Done()
+	stop()
+	logger.Info("shutdown signal received")
 
-	<-sig
-	logger.Println("shutdown signal received")
+	// Flip readiness first so the load balancer stops routing new
+	// traffic to this pod before we start draining connections.
+	ready.Store(false)
+	serviceUp.Set(0)
+	logger.Info("readiness gate closed, waiting before draining", "delay", shutdownPreDelay)
+	time.Sleep(shutdownPreDelay)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	drainCtx, cancel := context.WithTimeout(context.Background(), shutdownDrainTimeout)
 	defer cancel()
 
-	if err := server.Shutdown(ctx); err != nil {
-		logger.Printf("graceful shutdown failed: %v", err)
+	if err := server.Shutdown(drainCtx); err != nil {
+		logger.Error("graceful shutdown failed", "error", err)
 	} else {
-		logger.Println("server stopped cleanly")
+		logger.Info("server stopped cleanly")
 	}
 }
 
-func heartbeat(logger *log.Logger, interval time.Duration) {
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
+// newLogger builds the process-wide slog.Logger. The handler and level are
+// selectable via LOG_FORMAT (json|text, default json) and LOG_LEVEL (debug,
+// info, warn, error, default info) so operators can switch formats without a
+// rebuild.
+func newLogger() *slog.Logger {
+	opts := &slog.HandlerOptions{Level: logLevel(os.Getenv("LOG_LEVEL"))}
+
+	var handler slog.Handler
+	if os.Getenv("LOG_FORMAT") == "text" {
+		handler = slog.NewTextHandler(os.Stdout, opts)
+	} else {
+		handler = slog.NewJSONHandler(os.Stdout, opts)
+	}
+
+	return slog.New(handler)
+}
+
+func logLevel(v string) slog.Level {
+	switch v {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// requestID wraps next so every request carries an X-Request-ID: the
+// caller's value is reused if present, otherwise one is generated and
+// stored in the request context for downstream logging.
+func requestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if id == "" {
+			id = newRequestID()
+		}
+
+		w.Header().Set("X-Request-ID", id)
+		ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func newRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(buf)
+}
+
+// statusRecorder captures the status code and byte count written through
+// an http.ResponseWriter so accessLog can report them after the handler
+// returns.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	s.status = code
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	n, err := s.ResponseWriter.Write(b)
+	s.bytes += n
+	return n, err
+}
+
+// accessLog emits one structured record per request with the fields
+// operators need to correlate and aggregate without regex parsing.
+func accessLog(logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(rec, r)
+
+		duration := time.Since(start)
+		status := statusLabel(rec.status)
+
+		httpRequestsTotal.WithLabelValues(r.Method, r.URL.Path, status).Inc()
+		httpRequestDuration.WithLabelValues(r.Method, r.URL.Path, status).Observe(duration.Seconds())
 
-	for t := range ticker.C {
-		logger.Printf(
-			"heartbeat alive=true uptime=%s requests=%d",
-			time.Since(t.Add(-interval)).Truncate(time.Second),
-			atomic.LoadUint64(&requestCount),
+		logger.Info("request handled",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"remote", r.RemoteAddr,
+			"status", rec.status,
+			"bytes", rec.bytes,
+			"duration_ms", duration.Milliseconds(),
+			"request_id", r.Context().Value(requestIDKey{}),
 		)
+	})
+}
+
+// shutdownPreDelay is how long /readyz is held unhealthy before we start
+// draining connections, giving the Kubernetes Service time to remove this
+// pod's endpoint. It defaults to kube-proxy's worst-case detection window
+// (failure threshold * period) plus a small safety delta, and can be
+// overridden with SHUTDOWN_PREDELAY (a duration string, e.g. "5s").
+var shutdownPreDelay = envDuration("SHUTDOWN_PREDELAY", kubeFailThreshold*kubePeriodSeconds+shutdownDelta)
+
+// shutdownDrainTimeout bounds how long server.Shutdown waits for in-flight
+// requests to finish before the process exits. Override with
+// SHUTDOWN_DRAIN_TIMEOUT.
+var shutdownDrainTimeout = envDuration("SHUTDOWN_DRAIN_TIMEOUT", 5*time.Second)
+
+// HTTP server timeouts. None of these were previously set, which left the
+// server exposed to slow-client and connection-exhaustion attacks. Each is
+// overridable so operators can tune them without a rebuild.
+var (
+	readTimeout       = envDuration("READ_TIMEOUT", 5*time.Second)
+	readHeaderTimeout = envDuration("READ_HEADER_TIMEOUT", 5*time.Second)
+	writeTimeout      = envDuration("WRITE_TIMEOUT", 10*time.Second)
+	idleTimeout       = envDuration("IDLE_TIMEOUT", 120*time.Second)
+	requestTimeout    = envDuration("REQUEST_TIMEOUT", 10*time.Second)
+)
+
+// schedulerConcurrency bounds how many registered jobs may run at once.
+// Override with SCHEDULER_CONCURRENCY.
+var schedulerConcurrency = envInt("SCHEDULER_CONCURRENCY", 4)
+
+func envInt(name string, def int) int {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
 	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return def
+	}
+	return n
 }
 
-func backgroundWorker(logger *log.Logger, interval time.Duration) {
+const (
+	// kubeFailThreshold and kubePeriodSeconds mirror Kubernetes' default
+	// readiness probe settings (failureThreshold=3, periodSeconds=10) for
+	// the Service fronting this pod.
+	kubeFailThreshold = 3
+	kubePeriodSeconds = 10 * time.Second
+	shutdownDelta     = 2 * time.Second
+)
+
+// envDuration reads a duration from the named environment variable,
+// falling back to def if the variable is unset or malformed.
+func envDuration(name string, def time.Duration) time.Duration {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if d, err := time.ParseDuration(v); err == nil {
+		return d
+	}
+	return def
+}
+
+func heartbeat(ctx context.Context, interval time.Duration) {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		logger.Println("background job started")
+	start := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			uptimeSeconds.Set(time.Since(start).Seconds())
+		}
+	}
+}
 
-		// Simulated work
-		time.Sleep(500 * time.Millisecond)
+// runBackgroundJob is the original "background job" simulated work,
+// ported to the jobs.Scheduler's Func signature.
+func runBackgroundJob(ctx context.Context, logger *slog.Logger) error {
+	logger.Info("background job started", "job", "background")
 
-		logger.Println("background job completed")
+	select {
+	case <-time.After(500 * time.Millisecond):
+	case <-ctx.Done():
+		logger.Info("background job cancelled", "job", "background")
+		return ctx.Err()
 	}
+
+	logger.Info("background job completed", "job", "background")
+	return nil
 }
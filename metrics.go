@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/thenewkenya/init/internal/jobs"
+)
+
+var (
+	httpRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total number of HTTP requests handled, by method, path and status.",
+	}, []string{"method", "path", "status"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency in seconds.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "path", "status"})
+
+	uptimeSeconds = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "uptime_seconds",
+		Help: "Seconds since the process started.",
+	})
+
+	serviceUp = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "service_up",
+		Help: "1 if the service is accepting traffic, 0 during the pre-shutdown grace window.",
+	})
+)
+
+// metricsAddr is the listen address for the standalone /metrics endpoint,
+// kept separate from the main service address so scrapers don't compete
+// with application traffic. Override with METRICS_ADDR.
+var metricsAddr = envString("METRICS_ADDR", ":9090")
+
+func envString(name, def string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return def
+}
+
+// serveMetrics starts the Prometheus scrape endpoint and a /debug/jobs
+// introspection endpoint, and blocks until ctx is cancelled, then shuts
+// both down.
+func serveMetrics(ctx context.Context, logger *slog.Logger, scheduler *jobs.Scheduler) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/debug/jobs", debugJobsHandler(scheduler))
+
+	server := &http.Server{
+		Addr:    metricsAddr,
+		Handler: mux,
+	}
+
+	go func() {
+		logger.Info("metrics server listening", "addr", metricsAddr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error("metrics server error", "error", err)
+		}
+	}()
+
+	<-ctx.Done()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownDrainTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("metrics server shutdown failed", "error", err)
+	}
+}
+
+// statusLabel converts an HTTP status code to the string label Prometheus
+// metrics are keyed on.
+func statusLabel(status int) string {
+	return strconv.Itoa(status)
+}
+
+// debugJobsHandler reports each registered job's run history as JSON keyed
+// by job name, including LastError, which isn't cardinality-safe to expose
+// as a Prometheus label.
+func debugJobsHandler(scheduler *jobs.Scheduler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(scheduler.AllStats())
+	}
+}